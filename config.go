@@ -40,6 +40,7 @@ type RepoCloneConfig struct {
 	ShallowClone       bool   `yaml:"shallow_clone"`         // 是否浅克隆
 	ShallowDepth       int    `yaml:"shallow_depth"`         // 浅克隆深度
 	CleanupAfterReview bool   `yaml:"cleanup_after_review"`  // Review 后是否清理
+	DiffSource         string `yaml:"diff_source"`           // diff 来源："local"(默认) 或 "api"
 }
 
 // CodeGraphYAMLConfig CodeGraph 集成配置（YAML 形式）
@@ -49,6 +50,21 @@ type CodeGraphYAMLConfig struct {
 	IndexTimeout int    `yaml:"index_timeout"` // 建索引超时秒数
 }
 
+// ModelKeepaliveConfig 自托管模型预热/保活配置（Ollama/vLLM 等空闲会卸载模型的后端）
+type ModelKeepaliveConfig struct {
+	Enabled       bool `yaml:"enabled"`        // 是否启用
+	Interval      int  `yaml:"interval"`       // 保活 ping 间隔秒数，负数表示不定期保活
+	IdleThreshold int  `yaml:"idle_threshold"` // 空闲超过该秒数后，review 前先预热
+	WarmupTimeout int  `yaml:"warmup_timeout"` // 预热请求超时秒数
+}
+
+// LargePRConfig 超大 PR 摘要审查配置，各项上限 <= 0 表示不限制
+type LargePRConfig struct {
+	MaxFiles        int `yaml:"max_files"`         // 最大变更文件数
+	MaxChangedLines int `yaml:"max_changed_lines"` // 最大变更行数（新增 + 删除）
+	MaxDiffBytes    int `yaml:"max_diff_bytes"`    // 最大 diff 字节数
+}
+
 // Config 配置结构
 type Config struct {
 	AIApiURL           string `yaml:"ai_api_url"`
@@ -78,6 +94,12 @@ type Config struct {
 	// CodeGraph 集成配置
 	CodeGraph CodeGraphYAMLConfig `yaml:"codegraph"`
 
+	// 自托管模型预热/保活配置
+	ModelKeepalive ModelKeepaliveConfig `yaml:"model_keepalive"`
+
+	// 超大 PR 摘要审查配置
+	LargePR LargePRConfig `yaml:"large_pr"`
+
 	// VCS Provider 配置
 	VCSProvider string `yaml:"vcs_provider"` // "github" 或 "gitlab"
 
@@ -89,6 +111,10 @@ type Config struct {
 	GitlabToken        string `yaml:"gitlab_token"`
 	GitlabBaseURL      string `yaml:"gitlab_base_url"`
 	GitlabWebhookToken string `yaml:"gitlab_webhook_token"`
+
+	// 管理接口配置
+	AdminToken string `yaml:"admin_token"` // /admin/* 接口的访问 Token，为空时不启用管理接口
+	PublicURL  string `yaml:"public_url"`  // 服务对外可访问的地址，用于接入时创建 webhook
 }
 
 // 全局配置实例
@@ -199,6 +225,12 @@ func LoadConfig(filename string) error {
 		AppConfig.RepoClone.ShallowDepth = 100 // 默认深度 100
 	}
 	// ShallowClone 和 CleanupAfterReview 默认为 false，不需要显式设置
+	if AppConfig.RepoClone.DiffSource == "" {
+		AppConfig.RepoClone.DiffSource = "local" // 默认：在克隆的仓库中计算 diff
+	}
+	if AppConfig.RepoClone.DiffSource != "local" && AppConfig.RepoClone.DiffSource != "api" {
+		return fmt.Errorf("repo_clone.diff_source must be one of 'local', 'api', got: %s", AppConfig.RepoClone.DiffSource)
+	}
 
 	// CodeGraph 配置默认值
 	if AppConfig.CodeGraph.BinaryPath == "" {
@@ -208,6 +240,28 @@ func LoadConfig(filename string) error {
 		AppConfig.CodeGraph.IndexTimeout = 600 // 默认 10 分钟
 	}
 
+	// 模型保活配置默认值
+	if AppConfig.ModelKeepalive.Interval == 0 {
+		AppConfig.ModelKeepalive.Interval = 240 // 默认 4 分钟，短于 Ollama 默认的 5 分钟卸载时间
+	}
+	if AppConfig.ModelKeepalive.IdleThreshold == 0 {
+		AppConfig.ModelKeepalive.IdleThreshold = 300 // 默认 5 分钟
+	}
+	if AppConfig.ModelKeepalive.WarmupTimeout == 0 {
+		AppConfig.ModelKeepalive.WarmupTimeout = 600 // 默认 10 分钟，覆盖大模型的冷启动加载
+	}
+
+	// 超大 PR 上限默认值（设为负数可关闭对应检查）
+	if AppConfig.LargePR.MaxFiles == 0 {
+		AppConfig.LargePR.MaxFiles = 300
+	}
+	if AppConfig.LargePR.MaxChangedLines == 0 {
+		AppConfig.LargePR.MaxChangedLines = 20000
+	}
+	if AppConfig.LargePR.MaxDiffBytes == 0 {
+		AppConfig.LargePR.MaxDiffBytes = 2000000 // 默认 2MB
+	}
+
 	return nil
 }
 
@@ -256,6 +310,16 @@ func (c *Config) GetGitlabWebhookToken() string {
 	return c.GitlabWebhookToken
 }
 
+// GetAdminToken 获取管理接口 Token
+func (c *Config) GetAdminToken() string {
+	return c.AdminToken
+}
+
+// GetPublicURL 获取服务对外地址
+func (c *Config) GetPublicURL() string {
+	return c.PublicURL
+}
+
 // GetLineMatchStrategy 获取行号匹配策略
 func (c *Config) GetLineMatchStrategy() string {
 	return c.LineMatchStrategy
@@ -370,6 +434,10 @@ func (c *Config) GetRepoCloneCleanupAfterReview() bool {
 	return c.RepoClone.CleanupAfterReview
 }
 
+func (c *Config) GetRepoCloneDiffSource() string {
+	return c.RepoClone.DiffSource
+}
+
 // CodeGraph 配置 getter
 func (c *Config) GetCodeGraphEnabled() bool {
 	return c.CodeGraph.Enabled
@@ -382,3 +450,16 @@ func (c *Config) GetCodeGraphBinaryPath() string {
 func (c *Config) GetCodeGraphIndexTimeout() int {
 	return c.CodeGraph.IndexTimeout
 }
+
+// 超大 PR 摘要审查配置 getter
+func (c *Config) GetLargePRMaxFiles() int {
+	return c.LargePR.MaxFiles
+}
+
+func (c *Config) GetLargePRMaxChangedLines() int {
+	return c.LargePR.MaxChangedLines
+}
+
+func (c *Config) GetLargePRMaxDiffBytes() int {
+	return c.LargePR.MaxDiffBytes
+}
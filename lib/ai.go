@@ -19,9 +19,10 @@ type AIMessage struct {
 
 // AIRequest OpenAI 格式的请求
 type AIRequest struct {
-	Model    string      `json:"model"`
-	Messages []AIMessage `json:"messages"`
-	Stream   bool        `json:"stream"`
+	Model     string      `json:"model"`
+	Messages  []AIMessage `json:"messages"`
+	Stream    bool        `json:"stream"`
+	MaxTokens int         `json:"max_tokens,omitempty"`
 }
 
 // AIResponse OpenAI 格式的响应
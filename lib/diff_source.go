@@ -0,0 +1,78 @@
+package lib
+
+import "fmt"
+
+const (
+	DiffSourceLocal = "local" // 在克隆的工作目录中用 git 计算 diff
+	DiffSourceAPI   = "api"   // 通过 GitHub/GitLab API 获取 diff
+)
+
+// DiffSource 定义 PR/MR diff 的来源
+type DiffSource interface {
+	// GetDiff 获取 diff 以及该 diff 对应的 head commit SHA
+	// headSHA 为空表示来源无法确定，由调用方通过 API 获取
+	GetDiff(repo string, number int) (diff string, headSHA string, err error)
+
+	// Name 返回来源名称（用于日志）
+	Name() string
+}
+
+// APIDiffSource 通过 VCS 平台 API 获取 diff（受平台截断限制）
+type APIDiffSource struct {
+	Provider VCSProvider
+}
+
+// NewAPIDiffSource 创建 API diff 来源
+func NewAPIDiffSource(provider VCSProvider) *APIDiffSource {
+	return &APIDiffSource{Provider: provider}
+}
+
+// GetDiff 实现 DiffSource 接口
+func (s *APIDiffSource) GetDiff(repo string, number int) (string, string, error) {
+	diff, err := s.Provider.GetDiff(repo, number)
+	if err != nil {
+		return "", "", err
+	}
+	return diff, "", nil
+}
+
+// Name 实现 DiffSource 接口
+func (s *APIDiffSource) Name() string {
+	return DiffSourceAPI
+}
+
+// LocalGitDiffSource 在克隆的工作目录中通过 git 计算 diff。
+// diff、行号映射与检出的代码来自同一个 commit，且不受 API 的截断限制。
+type LocalGitDiffSource struct {
+	RepoManager *RepoManager
+	WorkDir     string
+	BranchInfo  BranchInfo
+}
+
+// NewLocalGitDiffSource 创建本地 git diff 来源
+func NewLocalGitDiffSource(rm *RepoManager, workDir string, branchInfo BranchInfo) *LocalGitDiffSource {
+	return &LocalGitDiffSource{
+		RepoManager: rm,
+		WorkDir:     workDir,
+		BranchInfo:  branchInfo,
+	}
+}
+
+// GetDiff 实现 DiffSource 接口，repo 与 number 已由克隆的工作目录确定，这里忽略
+func (s *LocalGitDiffSource) GetDiff(repo string, number int) (string, string, error) {
+	diff, err := s.RepoManager.GetDiffFromLocalRepo(s.WorkDir, s.BranchInfo.SourceBranch, s.BranchInfo.TargetBranch)
+	if err != nil {
+		return "", "", err
+	}
+
+	headSHA, err := s.RepoManager.GetHeadSHA(s.WorkDir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve local HEAD: %w", err)
+	}
+	return diff, headSHA, nil
+}
+
+// Name 实现 DiffSource 接口
+func (s *LocalGitDiffSource) Name() string {
+	return DiffSourceLocal
+}
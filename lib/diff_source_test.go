@@ -0,0 +1,61 @@
+package lib
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v, output: %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestLocalGitDiffSource_DiffMatchesCheckedOutHead(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	origin := t.TempDir()
+	runGit(t, origin, "init", "-q", "-b", "main")
+	if err := os.WriteFile(filepath.Join(origin, "a.txt"), []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, origin, "add", ".")
+	runGit(t, origin, "commit", "-q", "-m", "base")
+	runGit(t, origin, "checkout", "-q", "-b", "feature")
+	if err := os.WriteFile(filepath.Join(origin, "a.txt"), []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, origin, "commit", "-q", "-am", "add two")
+
+	workDir := filepath.Join(t.TempDir(), "work")
+	runGit(t, filepath.Dir(workDir), "clone", "-q", origin, workDir)
+	runGit(t, workDir, "checkout", "-q", "--detach", "origin/feature")
+	wantSHA := runGit(t, workDir, "rev-parse", "HEAD")
+
+	rm := NewRepoManager(filepath.Dir(workDir), 60, false, 0)
+	source := NewLocalGitDiffSource(rm, workDir, BranchInfo{SourceBranch: "feature", TargetBranch: "main"})
+
+	diff, headSHA, err := source.GetDiff("org/repo", 1)
+	if err != nil {
+		t.Fatalf("GetDiff failed: %v", err)
+	}
+	if !strings.Contains(diff, "+++ b/a.txt") || !strings.Contains(diff, "+two") {
+		t.Fatalf("unexpected diff:\n%s", diff)
+	}
+	if headSHA != wantSHA {
+		t.Fatalf("expected head %s, got %s", wantSHA, headSHA)
+	}
+}
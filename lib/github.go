@@ -7,6 +7,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -35,8 +36,11 @@ type githubPRResponse struct {
 	Labels []struct {
 		Name string `json:"name"`
 	} `json:"labels"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+	ChangedFiles int    `json:"changed_files"`
+	Additions    int    `json:"additions"`
+	Deletions    int    `json:"deletions"`
 }
 
 // NewGitHubClient 创建 GitHub 客户端
@@ -80,7 +84,7 @@ func (c *GitHubClient) GetPRDiff(repo string, prNum int) (string, error) {
 	const maxDiffLength = 240000
 	if len(diffText) > maxDiffLength {
 		log.Printf("⚠️ Diff truncated: original length %d, max %d", len(diffText), maxDiffLength)
-		diffText = diffText[:maxDiffLength] + "\n\n...(diff truncated due to size limit)"
+		diffText = diffText[:maxDiffLength] + "\n\n" + DiffTruncatedNotice
 	}
 
 	return diffText, nil
@@ -153,6 +157,9 @@ func (c *GitHubClient) GetPRInfo(repo string, prNum int) (*PRInfo, error) {
 		IsDraft:      prResp.Draft,
 		CreatedAt:    prResp.CreatedAt,
 		UpdatedAt:    prResp.UpdatedAt,
+		ChangedFiles: prResp.ChangedFiles,
+		Additions:    prResp.Additions,
+		Deletions:    prResp.Deletions,
 	}, nil
 }
 
@@ -480,7 +487,179 @@ func (c *GitHubClient) DeleteInlineComment(repo string, number int, commentID in
 	return nil
 }
 
+// EnsureWebhook 实现 VCSProvider 接口 - 确保仓库或组织上存在 pull_request 事件的 webhook
+func (c *GitHubClient) EnsureWebhook(target string, org bool, hookURL, secret string) (bool, error) {
+	hooksURL := fmt.Sprintf("https://api.github.com/repos/%s/hooks", target)
+	if org {
+		hooksURL = fmt.Sprintf("https://api.github.com/orgs/%s/hooks", target)
+	}
+
+	// 1. 检查是否已存在指向相同 URL 的 webhook（按 Link 头逐页查找）
+	for pageURL := hooksURL + "?per_page=100"; pageURL != ""; {
+		req, err := http.NewRequest("GET", pageURL, nil)
+		if err != nil {
+			return false, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+		req.Header.Set("Accept", "application/vnd.github+json")
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return false, fmt.Errorf("failed to list webhooks: %w", err)
+		}
+
+		if resp.StatusCode != 200 {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return false, fmt.Errorf("GitHub API error: %s, body: %s", resp.Status, string(body))
+		}
+
+		var hooks []struct {
+			Config struct {
+				URL string `json:"url"`
+			} `json:"config"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&hooks)
+		resp.Body.Close()
+		if err != nil {
+			return false, fmt.Errorf("failed to decode webhooks: %w", err)
+		}
+		for _, hook := range hooks {
+			if hook.Config.URL == hookURL {
+				return false, nil
+			}
+		}
+		pageURL = nextPageURL(resp.Header.Get("Link"))
+	}
+
+	// 2. 创建 webhook
+	hookBody := map[string]interface{}{
+		"name":   "web",
+		"active": true,
+		"events": []string{"pull_request"},
+		"config": map[string]string{
+			"url":          hookURL,
+			"content_type": "json",
+			"secret":       secret,
+			"insecure_ssl": "0",
+		},
+	}
+	jsonHook, err := json.Marshal(hookBody)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal webhook: %w", err)
+	}
+
+	createReq, err := http.NewRequest("POST", hooksURL, bytes.NewBuffer(jsonHook))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	createReq.Header.Set("Authorization", "Bearer "+c.Token)
+	createReq.Header.Set("Accept", "application/vnd.github+json")
+	createReq.Header.Set("Content-Type", "application/json")
+
+	createResp, err := c.HTTPClient.Do(createReq)
+	if err != nil {
+		return false, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	defer createResp.Body.Close()
+
+	if createResp.StatusCode != 201 {
+		body, _ := io.ReadAll(createResp.Body)
+		return false, fmt.Errorf("failed to create webhook, status: %s, body: %s", createResp.Status, string(body))
+	}
+	return true, nil
+}
+
+// GetLatestOpenPR 实现 VCSProvider 接口 - 获取最近更新的打开状态 PR
+func (c *GitHubClient) GetLatestOpenPR(repo string) (int, error) {
+	pullsURL := fmt.Sprintf("https://api.github.com/repos/%s/pulls?state=open&sort=updated&direction=desc&per_page=1", repo)
+
+	req, err := http.NewRequest("GET", pullsURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("GitHub API error: %s, body: %s", resp.Status, string(body))
+	}
+
+	var pulls []struct {
+		Number int `json:"number"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&pulls); err != nil {
+		return 0, fmt.Errorf("failed to decode pull requests: %w", err)
+	}
+	if len(pulls) == 0 {
+		return 0, nil
+	}
+	return pulls[0].Number, nil
+}
+
+// ListRecentRepos 实现 VCSProvider 接口 - 按最近推送时间列出组织下未归档的仓库
+func (c *GitHubClient) ListRecentRepos(org string, limit int) ([]string, error) {
+	reposURL := fmt.Sprintf("https://api.github.com/orgs/%s/repos?sort=pushed&direction=desc&per_page=%d", org, limit)
+
+	req, err := http.NewRequest("GET", reposURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("GitHub API error: %s, body: %s", resp.Status, string(body))
+	}
+
+	var repos []struct {
+		FullName string `json:"full_name"`
+		Archived bool   `json:"archived"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
+		return nil, fmt.Errorf("failed to decode repositories: %w", err)
+	}
+
+	names := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		if !repo.Archived {
+			names = append(names, repo.FullName)
+		}
+	}
+	return names, nil
+}
+
 // GetProviderType 实现 VCSProvider 接口
 func (c *GitHubClient) GetProviderType() string {
 	return ProviderTypeGitHub
 }
+
+// nextPageURL 从 Link 响应头中解析 rel="next" 的分页 URL，没有下一页时返回空
+func nextPageURL(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		segments := strings.Split(part, ";")
+		if len(segments) < 2 {
+			continue
+		}
+		for _, param := range segments[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(segments[0]), "<>")
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,18 @@
+package lib
+
+import "testing"
+
+func TestNextPageURL(t *testing.T) {
+	link := `<https://api.github.com/repositories/1/hooks?per_page=100&page=2>; rel="next", <https://api.github.com/repositories/1/hooks?per_page=100&page=3>; rel="last"`
+	if got := nextPageURL(link); got != "https://api.github.com/repositories/1/hooks?per_page=100&page=2" {
+		t.Fatalf("unexpected next page: %q", got)
+	}
+
+	last := `<https://api.github.com/repositories/1/hooks?per_page=100&page=1>; rel="first", <https://api.github.com/repositories/1/hooks?per_page=100&page=2>; rel="prev"`
+	if got := nextPageURL(last); got != "" {
+		t.Fatalf("expected no next page on last page, got %q", got)
+	}
+	if got := nextPageURL(""); got != "" {
+		t.Fatalf("expected no next page without Link header, got %q", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -40,12 +41,14 @@ type gitlabMRResponse struct {
 	Labels       []string `json:"labels"`
 	CreatedAt    string   `json:"created_at"`
 	UpdatedAt    string   `json:"updated_at"`
+	ChangesCount string   `json:"changes_count"` // 变更文件数，超出 GitLab 上限时形如 "1000+"
 }
 
 // MRChanges MR 变更信息
 type MRChanges struct {
-	SHA     string `json:"sha"`
-	Changes []struct {
+	SHA      string `json:"sha"`
+	Overflow bool   `json:"overflow"` // 变更超出 GitLab 上限，changes 不完整
+	Changes  []struct {
 		OldPath string `json:"old_path"`
 		NewPath string `json:"new_path"`
 		Diff    string `json:"diff"`
@@ -100,7 +103,10 @@ func (c *GitLabClient) GetDiff(repo string, mrNum int) (string, error) {
 	const maxDiffLength = 240000
 	if len(diffText) > maxDiffLength {
 		log.Printf("⚠️ Diff truncated: original length %d, max %d", len(diffText), maxDiffLength)
-		diffText = diffText[:maxDiffLength] + "\n\n...(diff truncated due to size limit)"
+		diffText = diffText[:maxDiffLength] + "\n\n" + DiffTruncatedNotice
+	} else if mrChanges.Overflow {
+		log.Printf("⚠️ MR changes overflow: GitLab returned a partial file list")
+		diffText = diffText + "\n\n" + DiffTruncatedNotice
 	}
 
 	return diffText, nil
@@ -176,6 +182,7 @@ func (c *GitLabClient) GetPRInfo(repo string, mrNum int) (*PRInfo, error) {
 		IsDraft:      isDraft,
 		CreatedAt:    mrResp.CreatedAt,
 		UpdatedAt:    mrResp.UpdatedAt,
+		ChangedFiles: parseChangesCount(mrResp.ChangesCount),
 	}, nil
 }
 
@@ -230,11 +237,23 @@ func (c *GitLabClient) PostInlineComment(repo string, mrNum int, commitSHA, path
 
 	discussionURL := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests/%d/discussions", c.BaseURL, encodedRepo, mrNum)
 
+	// 默认使用 MR 当前的 diff_refs；commitSHA 不是当前 head 时（审查期间有新的推送），
+	// 改用该 commit 对应的 MR diff 版本，保证评论位置与审查的 diff 一致
+	baseSHA, headSHA, startSHA := mrResp.DiffRefs.BaseSHA, mrResp.DiffRefs.HeadSHA, mrResp.DiffRefs.StartSHA
+	if commitSHA != "" && commitSHA != headSHA {
+		version, err := c.getDiffVersion(repo, mrNum, commitSHA)
+		if err != nil {
+			log.Printf("⚠️ Failed to find MR diff version for %s, anchoring to current head: %v", commitSHA, err)
+		} else {
+			baseSHA, headSHA, startSHA = version.BaseCommitSHA, version.HeadCommitSHA, version.StartCommitSHA
+		}
+	}
+
 	// 构建 position 对象
 	positionObj := map[string]interface{}{
-		"base_sha":      mrResp.DiffRefs.BaseSHA,
-		"head_sha":      mrResp.DiffRefs.HeadSHA,
-		"start_sha":     mrResp.DiffRefs.StartSHA,
+		"base_sha":      baseSHA,
+		"head_sha":      headSHA,
+		"start_sha":     startSHA,
 		"position_type": "text",
 		"new_path":      path,
 		"old_path":      path,
@@ -284,6 +303,55 @@ func (c *GitLabClient) PostInlineComment(repo string, mrNum int, commitSHA, path
 	return nil
 }
 
+// parseChangesCount 解析 changes_count（如 "42"、"1000+"），无法解析时返回 0
+func parseChangesCount(count string) int {
+	n, _ := strconv.Atoi(strings.TrimSuffix(count, "+"))
+	return n
+}
+
+// gitlabMRVersion MR diff 版本（每次推送生成一个版本）
+type gitlabMRVersion struct {
+	HeadCommitSHA  string `json:"head_commit_sha"`
+	BaseCommitSHA  string `json:"base_commit_sha"`
+	StartCommitSHA string `json:"start_commit_sha"`
+}
+
+// getDiffVersion 查找 head 为 commitSHA 的 MR diff 版本
+func (c *GitLabClient) getDiffVersion(repo string, mrNum int, commitSHA string) (*gitlabMRVersion, error) {
+	encodedRepo := url.PathEscape(repo)
+	versionsURL := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests/%d/versions?per_page=100", c.BaseURL, encodedRepo, mrNum)
+
+	req, err := http.NewRequest("GET", versionsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", c.Token)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MR versions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("GitLab API error: %s, body: %s", resp.Status, string(body))
+	}
+
+	var versions []gitlabMRVersion
+	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
+		return nil, fmt.Errorf("failed to decode MR versions: %w", err)
+	}
+
+	for _, version := range versions {
+		if version.HeadCommitSHA == commitSHA {
+			return &version, nil
+		}
+	}
+	return nil, fmt.Errorf("no diff version with head %s", commitSHA)
+}
+
 // GetIssueComments 获取 MR 的普通评论列表
 func (c *GitLabClient) GetIssueComments(repo string, mrNum int) ([]Comment, error) {
 	encodedRepo := url.PathEscape(repo)
@@ -551,6 +619,153 @@ func (c *GitLabClient) DeleteInlineComment(repo string, number int, commentID in
 	return c.DeleteComment(repo, number, commentID)
 }
 
+// EnsureWebhook 实现 VCSProvider 接口 - 确保项目或群组上存在 Merge Request 事件的 webhook
+// 注意：群组级 webhook 需要 GitLab Premium 及以上版本
+func (c *GitLabClient) EnsureWebhook(target string, org bool, hookURL, secret string) (bool, error) {
+	encodedTarget := url.PathEscape(target)
+	hooksURL := fmt.Sprintf("%s/api/v4/projects/%s/hooks", c.BaseURL, encodedTarget)
+	if org {
+		hooksURL = fmt.Sprintf("%s/api/v4/groups/%s/hooks", c.BaseURL, encodedTarget)
+	}
+
+	// 1. 检查是否已存在指向相同 URL 的 webhook（按 X-Next-Page 头逐页查找）
+	for page := "1"; page != ""; {
+		req, err := http.NewRequest("GET", hooksURL+"?per_page=100&page="+page, nil)
+		if err != nil {
+			return false, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("PRIVATE-TOKEN", c.Token)
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return false, fmt.Errorf("failed to list webhooks: %w", err)
+		}
+
+		if resp.StatusCode != 200 {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return false, fmt.Errorf("GitLab API error: %s, body: %s", resp.Status, string(body))
+		}
+
+		var hooks []struct {
+			URL string `json:"url"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&hooks)
+		resp.Body.Close()
+		if err != nil {
+			return false, fmt.Errorf("failed to decode webhooks: %w", err)
+		}
+		for _, hook := range hooks {
+			if hook.URL == hookURL {
+				return false, nil
+			}
+		}
+		page = resp.Header.Get("X-Next-Page")
+	}
+
+	// 2. 创建 webhook（只订阅 Merge Request 事件）
+	hookBody := map[string]interface{}{
+		"url":                     hookURL,
+		"token":                   secret,
+		"merge_requests_events":   true,
+		"push_events":             false,
+		"enable_ssl_verification": true,
+	}
+	jsonHook, err := json.Marshal(hookBody)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal webhook: %w", err)
+	}
+
+	createReq, err := http.NewRequest("POST", hooksURL, bytes.NewBuffer(jsonHook))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	createReq.Header.Set("PRIVATE-TOKEN", c.Token)
+	createReq.Header.Set("Content-Type", "application/json")
+
+	createResp, err := c.HTTPClient.Do(createReq)
+	if err != nil {
+		return false, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	defer createResp.Body.Close()
+
+	if createResp.StatusCode != 201 {
+		body, _ := io.ReadAll(createResp.Body)
+		return false, fmt.Errorf("failed to create webhook, status: %s, body: %s", createResp.Status, string(body))
+	}
+	return true, nil
+}
+
+// GetLatestOpenPR 实现 VCSProvider 接口 - 获取最近更新的打开状态 MR
+func (c *GitLabClient) GetLatestOpenPR(repo string) (int, error) {
+	encodedRepo := url.PathEscape(repo)
+	mrsURL := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests?state=opened&order_by=updated_at&sort=desc&per_page=1", c.BaseURL, encodedRepo)
+
+	req, err := http.NewRequest("GET", mrsURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.Token)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list merge requests: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("GitLab API error: %s, body: %s", resp.Status, string(body))
+	}
+
+	var mrs []struct {
+		IID int `json:"iid"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&mrs); err != nil {
+		return 0, fmt.Errorf("failed to decode merge requests: %w", err)
+	}
+	if len(mrs) == 0 {
+		return 0, nil
+	}
+	return mrs[0].IID, nil
+}
+
+// ListRecentRepos 实现 VCSProvider 接口 - 按最近活动时间列出群组（含子群组）下未归档的项目
+func (c *GitLabClient) ListRecentRepos(group string, limit int) ([]string, error) {
+	encodedGroup := url.PathEscape(group)
+	projectsURL := fmt.Sprintf("%s/api/v4/groups/%s/projects?include_subgroups=true&archived=false&order_by=last_activity_at&sort=desc&per_page=%d", c.BaseURL, encodedGroup, limit)
+
+	req, err := http.NewRequest("GET", projectsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.Token)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("GitLab API error: %s, body: %s", resp.Status, string(body))
+	}
+
+	var projects []struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&projects); err != nil {
+		return nil, fmt.Errorf("failed to decode projects: %w", err)
+	}
+
+	names := make([]string, 0, len(projects))
+	for _, project := range projects {
+		names = append(names, project.PathWithNamespace)
+	}
+	return names, nil
+}
+
 // GetProviderType 实现 VCSProvider 接口
 func (c *GitLabClient) GetProviderType() string {
 	return ProviderTypeGitLab
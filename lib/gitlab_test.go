@@ -0,0 +1,44 @@
+package lib
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGitLabPostInlineComment_AnchorsToReviewedVersion(t *testing.T) {
+	var position map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/merge_requests/7"):
+			// 审查期间有新的推送，MR 当前 head 已变为 new-head
+			_, _ = w.Write([]byte(`{"diff_refs":{"base_sha":"base-2","head_sha":"new-head","start_sha":"start-2"}}`))
+		case strings.HasSuffix(r.URL.Path, "/merge_requests/7/versions"):
+			_, _ = w.Write([]byte(`[
+				{"head_commit_sha":"new-head","base_commit_sha":"base-2","start_commit_sha":"start-2"},
+				{"head_commit_sha":"reviewed","base_commit_sha":"base-1","start_commit_sha":"start-1"}
+			]`))
+		case strings.HasSuffix(r.URL.Path, "/merge_requests/7/discussions"):
+			var body struct {
+				Position map[string]any `json:"position"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			position = body.Position
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewGitLabClient("token", srv.URL)
+	if err := client.PostInlineComment("group/repo", 7, "reviewed", "main.go", 0, "body", 0, 3); err != nil {
+		t.Fatalf("PostInlineComment failed: %v", err)
+	}
+
+	if position["head_sha"] != "reviewed" || position["base_sha"] != "base-1" || position["start_sha"] != "start-1" {
+		t.Fatalf("expected position anchored to reviewed version, got %v", position)
+	}
+}
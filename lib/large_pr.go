@@ -0,0 +1,213 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxSummaryFiles 摘要评论中最多列出的文件数
+const maxSummaryFiles = 50
+
+// DiffTruncatedNotice GetDiff 截断 diff 时追加的标记
+const DiffTruncatedNotice = "...(diff truncated due to size limit)"
+
+// DiffLimits 单次审查可处理的 diff 规模上限，<= 0 表示不限制该项
+type DiffLimits struct {
+	MaxFiles        int // 最大变更文件数
+	MaxChangedLines int // 最大变更行数（新增 + 删除）
+	MaxDiffBytes    int // 最大 diff 字节数
+}
+
+// DiffScale PR/MR 的变更规模
+// API 返回的 diff 可能被截断，此时优先采用平台元数据中的完整统计。
+type DiffScale struct {
+	Files        int  // 变更文件数
+	AddedLines   int  // 新增行数
+	DeletedLines int  // 删除行数
+	Truncated    bool // diff 已被截断，解析出的文件列表不完整
+}
+
+// MeasureDiff 计算变更规模：取 diff 解析结果与平台元数据（info 可为 nil）中较大的值
+func MeasureDiff(diff string, summaries []FileSummary, info *PRInfo) DiffScale {
+	added, deleted := sumChangedLines(summaries)
+	scale := DiffScale{
+		Files:        len(summaries),
+		AddedLines:   added,
+		DeletedLines: deleted,
+		Truncated:    IsDiffTruncated(diff),
+	}
+	if info == nil {
+		return scale
+	}
+
+	if info.ChangedFiles > scale.Files {
+		scale.Files = info.ChangedFiles
+	}
+	if info.Additions+info.Deletions > scale.AddedLines+scale.DeletedLines {
+		scale.AddedLines, scale.DeletedLines = info.Additions, info.Deletions
+	}
+	return scale
+}
+
+// Partial 解析出的文件列表是否只是 PR/MR 的一部分
+func (s DiffScale) Partial(summaries []FileSummary) bool {
+	return s.Truncated || s.Files > len(summaries)
+}
+
+// Check 检查 diff 是否超出上限，返回超出项的说明；未超出时返回空
+// diff 被截断时无法完整审查，只要启用了任一上限即视为超限。
+func (l DiffLimits) Check(diff string, scale DiffScale) []string {
+	var reasons []string
+
+	if l.MaxFiles > 0 && scale.Files > l.MaxFiles {
+		reasons = append(reasons, fmt.Sprintf("变更文件数 %d，超过上限 %d", scale.Files, l.MaxFiles))
+	}
+
+	changedLines := scale.AddedLines + scale.DeletedLines
+	if l.MaxChangedLines > 0 && changedLines > l.MaxChangedLines {
+		reasons = append(reasons, fmt.Sprintf("变更行数 %d，超过上限 %d", changedLines, l.MaxChangedLines))
+	}
+
+	if l.MaxDiffBytes > 0 && len(diff) > l.MaxDiffBytes {
+		reasons = append(reasons, fmt.Sprintf("diff 大小 %d 字节，超过上限 %d 字节", len(diff), l.MaxDiffBytes))
+	}
+
+	if scale.Truncated && l.enabled() {
+		reasons = append(reasons, "diff 超出平台 API 的返回上限已被截断，无法完整审查")
+	}
+
+	return reasons
+}
+
+// enabled 是否启用了任一上限
+func (l DiffLimits) enabled() bool {
+	return l.MaxFiles > 0 || l.MaxChangedLines > 0 || l.MaxDiffBytes > 0
+}
+
+// BuildSummaryOnlyReview 为超出上限的 PR/MR 生成摘要评论：
+// 说明未做逐行审查的原因，列出变更文件与风险提示，并建议拆分。
+// diff 被截断时文件列表与风险提示只基于已获取的部分，评论中会注明。
+func BuildSummaryOnlyReview(summaries []FileSummary, reasons []string, limits DiffLimits, scale DiffScale) string {
+	var builder strings.Builder
+
+	builder.WriteString("### ⚠️ 超大 PR：仅提供摘要审查\n\n")
+	builder.WriteString("本次变更超出了单次审查的处理上限，AI **未进行逐行审查，也不会发布行内评论**，以下内容仅基于文件列表生成：\n")
+	for _, reason := range reasons {
+		builder.WriteString(fmt.Sprintf("- %s\n", reason))
+	}
+
+	// 文件列表（按变更行数降序，只列出前 maxSummaryFiles 个）
+	partial := scale.Partial(summaries)
+	if partial {
+		builder.WriteString(fmt.Sprintf("\n### 变更文件（共 %d 个，%s；diff 已截断，以下仅基于获取到的 %d 个文件）\n\n",
+			scale.Files, getChangeStats(scale.AddedLines, scale.DeletedLines), len(summaries)))
+	} else {
+		builder.WriteString(fmt.Sprintf("\n### 变更文件（共 %d 个，%s）\n\n", scale.Files, getChangeStats(scale.AddedLines, scale.DeletedLines)))
+	}
+
+	sorted := append([]FileSummary{}, summaries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].AddedLines+sorted[i].DeletedLines > sorted[j].AddedLines+sorted[j].DeletedLines
+	})
+
+	builder.WriteString("| 文件 | 变更 | 标记 |\n")
+	builder.WriteString("|------|------|------|\n")
+	for i, summary := range sorted {
+		if i >= maxSummaryFiles {
+			builder.WriteString(fmt.Sprintf("\n...其余 %d 个文件未列出\n", len(sorted)-maxSummaryFiles))
+			break
+		}
+		builder.WriteString(fmt.Sprintf("| %s `%s` | %s | %s |\n",
+			getChangeIndicator(summary.ChangeType), summary.Path,
+			getChangeStats(summary.AddedLines, summary.DeletedLines), getFileFlags(summary)))
+	}
+
+	// 风险提示
+	builder.WriteString("\n### 风险提示\n\n")
+	if partial {
+		builder.WriteString("- ⚠️ 以下提示仅覆盖获取到的部分文件，其余文件未分析\n")
+	}
+	for _, note := range buildRiskNotes(summaries) {
+		builder.WriteString(fmt.Sprintf("- %s\n", note))
+	}
+
+	// 拆分建议
+	builder.WriteString("\n### 建议\n\n")
+	builder.WriteString("- 请将本 PR 拆分为多个较小的 PR（例如生成代码、数据迁移、业务逻辑分别提交），以便获得完整的逐行审查")
+	if limits.MaxFiles > 0 || limits.MaxChangedLines > 0 {
+		builder.WriteString(fmt.Sprintf("，建议每个 PR 控制在 %s 以内", describeLimits(limits)))
+	}
+	builder.WriteString("\n")
+	builder.WriteString("- 生成代码建议单独提交，或不纳入版本控制、在构建时生成\n")
+
+	return builder.String()
+}
+
+// buildRiskNotes 根据文件属性生成风险提示
+func buildRiskNotes(summaries []FileSummary) []string {
+	var generated, migrations, configs, tests, deletedFiles int
+	var generatedLines int
+	for _, summary := range summaries {
+		if summary.IsGenerated {
+			generated++
+			generatedLines += summary.AddedLines + summary.DeletedLines
+		}
+		if summary.IsMigration {
+			migrations++
+		}
+		if summary.IsConfig {
+			configs++
+		}
+		if summary.IsTestFile {
+			tests++
+		}
+		if summary.ChangeType == "deleted" {
+			deletedFiles++
+		}
+	}
+
+	var notes []string
+	if generated > 0 {
+		notes = append(notes, fmt.Sprintf("🤖 包含 %d 个生成文件（共 %d 行变更），请确认生成工具版本一致，且无手工修改", generated, generatedLines))
+	}
+	if migrations > 0 {
+		notes = append(notes, fmt.Sprintf("🗃️ 包含 %d 个数据库迁移文件，需人工重点审查兼容性与回滚方案", migrations))
+	}
+	if configs > 0 {
+		notes = append(notes, fmt.Sprintf("⚙️ 包含 %d 个配置/依赖文件，需确认对部署环境的影响", configs))
+	}
+	if deletedFiles > 0 {
+		notes = append(notes, fmt.Sprintf("🗑️ 删除了 %d 个文件，需确认没有遗留引用", deletedFiles))
+	}
+	if tests == 0 {
+		notes = append(notes, "🧪 未包含测试文件，大规模变更缺少测试覆盖风险较高")
+	} else {
+		notes = append(notes, fmt.Sprintf("🧪 包含 %d 个测试文件", tests))
+	}
+	return notes
+}
+
+// IsDiffTruncated diff 是否被 GetDiff 截断
+func IsDiffTruncated(diff string) bool {
+	return strings.HasSuffix(diff, DiffTruncatedNotice)
+}
+
+func sumChangedLines(summaries []FileSummary) (added, deleted int) {
+	for _, summary := range summaries {
+		added += summary.AddedLines
+		deleted += summary.DeletedLines
+	}
+	return added, deleted
+}
+
+func describeLimits(limits DiffLimits) string {
+	var parts []string
+	if limits.MaxFiles > 0 {
+		parts = append(parts, fmt.Sprintf("%d 个文件", limits.MaxFiles))
+	}
+	if limits.MaxChangedLines > 0 {
+		parts = append(parts, fmt.Sprintf("%d 行变更", limits.MaxChangedLines))
+	}
+	return strings.Join(parts, "、")
+}
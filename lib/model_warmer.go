@@ -0,0 +1,189 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ModelWarmer 自托管模型（Ollama/vLLM 等）的预热与保活
+//
+// 这类后端在空闲一段时间后会卸载模型，下一次请求需要重新加载，冷启动可能长达数分钟。
+// ModelWarmer 定期发送极小的请求让模型保持常驻，并在长时间空闲后的首个 review 前先做一次预热。
+type ModelWarmer struct {
+	client        *AIClient
+	interval      time.Duration
+	idleThreshold time.Duration
+
+	mu         sync.Mutex
+	lastActive time.Time
+	warming    chan struct{} // 非 nil 表示预热请求进行中，预热结束时关闭
+	stop       chan struct{}
+}
+
+// NewModelWarmer 创建模型预热器
+// interval <= 0 表示不定期保活，只在空闲超过 idleThreshold 后预热
+func NewModelWarmer(apiURL, apiKey, model string, interval, idleThreshold, warmupTimeout int) *ModelWarmer {
+	client := NewAIClient(apiURL, apiKey, model, "", "")
+	client.HTTPClient = &http.Client{Timeout: time.Duration(warmupTimeout) * time.Second}
+
+	return &ModelWarmer{
+		client:        client,
+		interval:      time.Duration(interval) * time.Second,
+		idleThreshold: time.Duration(idleThreshold) * time.Second,
+		stop:          make(chan struct{}),
+	}
+}
+
+// Start 启动后台保活任务（interval <= 0 时不启动）
+func (w *ModelWarmer) Start() {
+	if w == nil || w.interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		log.Printf("🔥 Model keepalive started (model=%s, every %v)", w.client.Model, w.interval)
+
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+			}
+
+			// 半个周期内有过真实调用时跳过，避免和 review 抢占推理资源。
+			// 不能用整个周期比较：上一次 ping 结束时刷新了 lastActive，下一个 tick 时空闲时长必然略小于 interval，
+			// 会导致每隔一个 tick 才 ping 一次，实际间隔翻倍而超过后端的卸载时间。
+			if w.idleFor() < w.interval/2 {
+				continue
+			}
+			if err := w.ping(); err != nil {
+				log.Printf("⚠️ Model keepalive ping failed: %v", err)
+				continue
+			}
+			w.MarkActive()
+		}
+	}()
+}
+
+// Stop 停止后台保活任务
+func (w *ModelWarmer) Stop() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	select {
+	case <-w.stop:
+	default:
+		close(w.stop)
+	}
+}
+
+// WarmUpIfIdle 模型空闲超过阈值时同步发送预热请求，确保随后的 review 不会承担冷启动延迟
+// 并发的 review 会等待同一次预热完成，不会重复预热；预热请求期间不持有锁，MarkActive 不会被网络 I/O 阻塞。
+func (w *ModelWarmer) WarmUpIfIdle() {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	if !w.lastActive.IsZero() && time.Since(w.lastActive) < w.idleThreshold {
+		w.mu.Unlock()
+		return
+	}
+	if warming := w.warming; warming != nil {
+		w.mu.Unlock()
+		<-warming
+		return
+	}
+	warming := make(chan struct{})
+	w.warming = warming
+	w.mu.Unlock()
+
+	defer func() {
+		w.mu.Lock()
+		w.warming = nil
+		w.mu.Unlock()
+		close(warming)
+	}()
+
+	log.Printf("🔥 Warming up model %s before review...", w.client.Model)
+	start := time.Now()
+	if err := w.ping(); err != nil {
+		// 预热失败不阻塞 review，由正式请求自行处理错误
+		log.Printf("⚠️ Model warm-up failed after %v: %v", time.Since(start).Round(time.Millisecond), err)
+		return
+	}
+	w.MarkActive()
+	log.Printf("✅ Model warmed up in %v", time.Since(start).Round(time.Millisecond))
+}
+
+// MarkActive 记录模型最近一次被调用的时间
+func (w *ModelWarmer) MarkActive() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	w.lastActive = time.Now()
+	w.mu.Unlock()
+}
+
+// idleFor 距上次调用的时长（从未调用时视为无限空闲）
+func (w *ModelWarmer) idleFor() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.lastActive.IsZero() {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Since(w.lastActive)
+}
+
+// ping 发送只生成 1 个 token 的请求，触发后端加载模型
+func (w *ModelWarmer) ping() error {
+	payload := AIRequest{
+		Model: w.client.Model,
+		Messages: []AIMessage{
+			{
+				Role:    "user",
+				Content: "ping",
+			},
+		},
+		Stream:    false,
+		MaxTokens: 1,
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ping request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", w.client.APIUrl, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+w.client.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("ping request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("ping returned status: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package lib
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestModelWarmer_WarmUpOnlyWhenIdle(t *testing.T) {
+	var pings int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&pings, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	w := NewModelWarmer(srv.URL, "key", "model", 0, 300, 5)
+
+	// 从未调用过，首次 review 前应预热
+	w.WarmUpIfIdle()
+	if got := atomic.LoadInt32(&pings); got != 1 {
+		t.Fatalf("expected 1 warm-up ping, got %d", got)
+	}
+
+	// 刚刚活跃过，不应重复预热
+	w.MarkActive()
+	w.WarmUpIfIdle()
+	if got := atomic.LoadInt32(&pings); got != 1 {
+		t.Fatalf("expected no extra ping while active, got %d", got)
+	}
+}
+
+func TestModelWarmer_KeepalivePingsEveryTick(t *testing.T) {
+	var pings int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 模拟有一定耗时的 ping，ping 结束后下一个 tick 的空闲时长小于 interval
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&pings, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	w := NewModelWarmer(srv.URL, "key", "model", 0, 300, 5)
+	w.interval = 50 * time.Millisecond
+	w.Start()
+	defer w.Stop()
+
+	time.Sleep(w.interval*6 + w.interval/2)
+	if got := atomic.LoadInt32(&pings); got < 5 {
+		t.Fatalf("expected a ping on every tick (>= 5 in 6 ticks), got %d", got)
+	}
+}
+
+func TestModelWarmer_MarkActiveNotBlockedByWarmUp(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	w := NewModelWarmer(srv.URL, "key", "model", 0, 300, 5)
+	go w.WarmUpIfIdle()
+	<-started
+
+	done := make(chan struct{})
+	go func() {
+		w.MarkActive()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("MarkActive blocked while warm-up request was in flight")
+	}
+}
+
+func TestModelWarmer_NilSafe(t *testing.T) {
+	var w *ModelWarmer
+	w.Start()
+	w.WarmUpIfIdle()
+	w.MarkActive()
+	w.Stop()
+}
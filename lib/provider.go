@@ -23,6 +23,9 @@ type PRInfo struct {
 	IsDraft      bool
 	CreatedAt    string
 	UpdatedAt    string
+	ChangedFiles int // 变更文件数（平台元数据，0 表示未知）
+	Additions    int // 新增行数（GitLab 不提供，为 0）
+	Deletions    int // 删除行数（GitLab 不提供，为 0）
 }
 
 // VCSProvider 定义版本控制系统提供商的统一接口
@@ -65,6 +68,16 @@ type VCSProvider interface {
 	// DeleteInlineComment 删除行内评论
 	DeleteInlineComment(repo string, number int, commentID int64) error
 
+	// EnsureWebhook 确保仓库（org 为 true 时为 GitHub 组织 / GitLab 群组）上存在指向 hookURL 的 webhook
+	// 已存在相同 URL 的 webhook 时不重复创建，返回 created=false
+	EnsureWebhook(target string, org bool, hookURL, secret string) (created bool, err error)
+
+	// GetLatestOpenPR 获取仓库最近更新的打开状态 PR/MR 编号，没有时返回 0
+	GetLatestOpenPR(repo string) (int, error)
+
+	// ListRecentRepos 按最近活动时间列出组织/群组下的仓库（最多 limit 个）
+	ListRecentRepos(org string, limit int) ([]string, error)
+
 	// GetProviderType 返回提供商类型（用于日志）
 	GetProviderType() string
 }
@@ -296,6 +296,21 @@ func (rm *RepoManager) GetDiffFromLocalRepo(workDir, sourceBranch, targetBranch
 	return stdout.String(), nil
 }
 
+// GetHeadSHA 获取工作目录当前检出的 commit SHA
+func (rm *RepoManager) GetHeadSHA(workDir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = workDir
+
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git rev-parse HEAD failed: %w, stderr: %s", err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
 // ensureMergeBase 查找 merge-base；浅克隆下若 merge-base 不可达，会逐步加深
 // 直至可达或转为 unshallow。
 func (rm *RepoManager) ensureMergeBase(workDir, ref1, ref2, sourceBranch, targetBranch string) (string, error) {
@@ -36,11 +36,22 @@ func main() {
 		log.Fatalf("❌ Unsupported VCS provider: %s", AppConfig.VCSProvider)
 	}
 
+	// 注册管理接口（仅在配置了 admin_token 时启用）
+	if AppConfig.AdminToken != "" {
+		http.HandleFunc("/admin/onboard", router.HandleOnboard)
+		log.Printf("🔧 Admin endpoints enabled (/admin/onboard)")
+	}
+
 	// 启动定期清理任务（如果使用需要克隆仓库的 CLI 模式）
 	if AppConfig.ReviewMode == "claude_cli" || AppConfig.ReviewMode == "codex" {
 		startCleanupTask()
 	}
 
+	// 启动模型预热/保活（自托管 Ollama/vLLM 等空闲会卸载模型的后端）
+	if AppConfig.ModelKeepalive.Enabled {
+		startModelKeepalive()
+	}
+
 	// 启动服务
 	log.Printf("🚀 PR Review Service started on :%s", AppConfig.Port)
 	log.Printf("   AI Service: %s", AppConfig.AIApiURL)
@@ -84,3 +95,19 @@ func startCleanupTask() {
 		}
 	}()
 }
+
+// startModelKeepalive 创建模型预热器并启动定期保活
+func startModelKeepalive() {
+	warmer := lib.NewModelWarmer(
+		AppConfig.AIApiURL,
+		AppConfig.AIApiKey,
+		AppConfig.AIModel,
+		AppConfig.ModelKeepalive.Interval,
+		AppConfig.ModelKeepalive.IdleThreshold,
+		AppConfig.ModelKeepalive.WarmupTimeout,
+	)
+	router.SetModelWarmer(warmer)
+	warmer.Start()
+
+	log.Printf("🔥 Model warm-up enabled (idle threshold %ds)", AppConfig.ModelKeepalive.IdleThreshold)
+}
@@ -0,0 +1,198 @@
+package router
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"pr-review/lib"
+	"strings"
+)
+
+// OnboardRequest 仓库/组织接入请求体结构
+type OnboardRequest struct {
+	Repo          string `json:"repo,omitempty"`            // owner/repo，与 org 二选一
+	Org           string `json:"org,omitempty"`             // GitHub 组织或 GitLab 群组，与 repo 二选一
+	Provider      string `json:"provider,omitempty"`        // 可选，必须与服务配置的 vcs_provider 一致
+	Engine        string `json:"engine,omitempty"`          // 可选：冒烟测试使用的 review engine
+	SkipSmokeTest bool   `json:"skip_smoke_test,omitempty"` // 是否跳过冒烟测试
+}
+
+// OnboardResponse 接入结果
+type OnboardResponse struct {
+	Provider       string         `json:"provider"`
+	Target         string         `json:"target"`
+	Scope          string         `json:"scope"` // repo 或 org
+	WebhookURL     string         `json:"webhook_url"`
+	WebhookCreated bool           `json:"webhook_created"` // false 表示已存在，未重复创建
+	ServiceConfig  map[string]any `json:"service_config"`  // 服务全局审查配置；服务没有按仓库保存的配置，接入不会写入任何配置
+	SmokeTest      string         `json:"smoke_test"`      // started / skipped 及原因
+	SmokeTestRepo  string         `json:"smoke_test_repo,omitempty"`
+	SmokeTestPR    int            `json:"smoke_test_pr,omitempty"`
+}
+
+// maxSmokeTestRepos 组织接入时最多检查的最近活跃仓库数
+const maxSmokeTestRepos = 10
+
+// runSmokeTest 异步触发冒烟测试 review（测试中替换）
+var runSmokeTest = func(repo string, prNum int, providerType, token, engine string) {
+	go ProcessReview(repo, prNum, providerType, token, engine)
+}
+
+// HandleOnboard 处理仓库/组织接入：创建指向本服务的 webhook 并对最近的 PR 触发一次冒烟测试
+func HandleOnboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 1. 校验管理员 Token
+	adminToken := appConfig.GetAdminToken()
+	if adminToken == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Admin-Token")), []byte(adminToken)) != 1 {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// 2. 解析请求
+	var req OnboardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	req.Repo = strings.TrimSpace(req.Repo)
+	req.Org = strings.TrimSpace(req.Org)
+	if (req.Repo == "") == (req.Org == "") {
+		http.Error(w, "Exactly one of repo or org is required", http.StatusBadRequest)
+		return
+	}
+
+	reviewEngine := strings.TrimSpace(req.Engine)
+	if reviewEngine != "" && reviewEngine != "api" && reviewEngine != "claude_cli" && reviewEngine != "codex" {
+		http.Error(w, "Invalid engine, must be one of: api, claude_cli, codex", http.StatusBadRequest)
+		return
+	}
+
+	// 3. /webhook 只处理配置的 VCS Provider 的事件，因此只能接入同一平台的仓库
+	providerType := req.Provider
+	if providerType == "" {
+		providerType = appConfig.GetVCSProvider()
+	}
+	if providerType != appConfig.GetVCSProvider() {
+		http.Error(w, fmt.Sprintf("Provider %s does not match the service vcs_provider %s", providerType, appConfig.GetVCSProvider()), http.StatusBadRequest)
+		return
+	}
+
+	publicURL := strings.TrimSuffix(appConfig.GetPublicURL(), "/")
+	if publicURL == "" {
+		http.Error(w, "public_url is not configured", http.StatusPreconditionFailed)
+		return
+	}
+	hookURL := publicURL + "/webhook"
+
+	// 4. 获取管理员 Token（需要管理 webhook 的权限）与 webhook 密钥
+	var token, secret, serviceToken string
+	var vcsClient lib.VCSProvider
+	switch providerType {
+	case lib.ProviderTypeGitHub:
+		token = r.Header.Get("X-Github-Token")
+		secret = webhookSecret
+		serviceToken = appConfig.GetGithubToken()
+		vcsClient = lib.NewGitHubClient(token)
+	case lib.ProviderTypeGitLab:
+		token = r.Header.Get("PRIVATE-TOKEN")
+		secret = gitlabWebhookToken
+		serviceToken = appConfig.GetGitlabToken()
+		vcsClient = lib.NewGitLabClient(token, appConfig.GetGitlabBaseURL())
+	default:
+		http.Error(w, fmt.Sprintf("Unsupported provider: %s", providerType), http.StatusBadRequest)
+		return
+	}
+	if token == "" {
+		http.Error(w, "Admin-scoped VCS token is required (X-Github-Token or PRIVATE-TOKEN header)", http.StatusBadRequest)
+		return
+	}
+	if secret == "" {
+		http.Error(w, "Webhook secret is not configured, refusing to create an unauthenticated webhook", http.StatusPreconditionFailed)
+		return
+	}
+
+	target, scope := req.Repo, "repo"
+	if req.Org != "" {
+		target, scope = req.Org, "org"
+	}
+
+	// 5. 创建 webhook（已存在时跳过）
+	created, err := vcsClient.EnsureWebhook(target, scope == "org", hookURL, secret)
+	if err != nil {
+		log.Printf("❌ Onboard %s %s failed: %v", scope, target, err)
+		http.Error(w, fmt.Sprintf("Failed to create webhook: %v", err), http.StatusBadGateway)
+		return
+	}
+	if created {
+		log.Printf("🔗 Webhook created for %s %s -> %s", scope, target, hookURL)
+	} else {
+		log.Printf("🔗 Webhook already exists for %s %s -> %s", scope, target, hookURL)
+	}
+
+	resp := OnboardResponse{
+		Provider:       providerType,
+		Target:         target,
+		Scope:          scope,
+		WebhookURL:     hookURL,
+		WebhookCreated: created,
+		ServiceConfig: map[string]any{
+			"review_mode":          appConfig.GetReviewMode(),
+			"inline_review":        appConfig.GetInlineIssueComment(),
+			"comment_only_changes": appConfig.GetCommentOnlyChanges(),
+			"line_match_strategy":  appConfig.GetLineMatchStrategy(),
+		},
+	}
+
+	// 6. 冒烟测试：用服务自身的 Token 对最近更新的 PR/MR 触发一次 review，验证端到端权限
+	// 组织接入时依次检查最近活跃的仓库，取第一个有打开 PR/MR 的仓库
+	if req.SkipSmokeTest {
+		resp.SmokeTest = "skipped: disabled by request"
+	} else {
+		repos := []string{target}
+		if scope == "org" {
+			repos, err = vcsClient.ListRecentRepos(target, maxSmokeTestRepos)
+			if err != nil {
+				log.Printf("⚠️ Onboard %s: failed to list repositories for smoke test: %v", target, err)
+				resp.SmokeTest = fmt.Sprintf("skipped: failed to list repositories: %v", err)
+				repos = nil
+			}
+		}
+
+		for _, repo := range repos {
+			prNumber, err := vcsClient.GetLatestOpenPR(repo)
+			if err != nil {
+				log.Printf("⚠️ Onboard %s: failed to find recent PR for smoke test: %v", repo, err)
+				resp.SmokeTest = fmt.Sprintf("skipped: failed to list open PRs in %s: %v", repo, err)
+				continue
+			}
+			if prNumber == 0 {
+				continue
+			}
+
+			log.Printf("🧪 Onboard %s: starting smoke-test review on %s#%d (engine: %s)", target, repo, prNumber, chooseEngineLabel(reviewEngine))
+			runSmokeTest(repo, prNumber, providerType, serviceToken, reviewEngine)
+			resp.SmokeTest = "started"
+			resp.SmokeTestRepo = repo
+			resp.SmokeTestPR = prNumber
+			break
+		}
+
+		if resp.SmokeTest == "" {
+			resp.SmokeTest = "skipped: no open PR found"
+			if scope == "org" {
+				resp.SmokeTest = fmt.Sprintf("skipped: no open PR found in %d recently active repositories", len(repos))
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,205 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// gitlabTestConfig 将 VCS Provider 切换为指向 stub 服务的 GitLab
+type gitlabTestConfig struct {
+	testConfig
+	baseURL string
+}
+
+func (gitlabTestConfig) GetVCSProvider() string     { return "gitlab" }
+func (c gitlabTestConfig) GetGitlabBaseURL() string { return c.baseURL }
+
+// setupGitLabOnboard 启动 GitLab API stub：webhook 列表分两页，existing 为 true 时第二页包含本服务的 webhook；
+// group/idle 没有打开的 MR，group/app 最近的 MR 为 !42
+func setupGitLabOnboard(t *testing.T, existing bool) (created *int, smokeTests *[]string) {
+	t.Helper()
+	hookURL := "https://review.example.com/webhook"
+	created, smokeTests = new(int), new([]string)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "admin-gl-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		path := r.URL.EscapedPath()
+		switch {
+		case strings.HasSuffix(path, "/hooks") && r.Method == http.MethodGet:
+			hooks := []map[string]string{{"url": "https://other.example.com/hook"}}
+			if r.URL.Query().Get("page") == "1" {
+				w.Header().Set("X-Next-Page", "2")
+			} else if existing {
+				hooks = append(hooks, map[string]string{"url": hookURL})
+			}
+			_ = json.NewEncoder(w).Encode(hooks)
+		case strings.HasSuffix(path, "/hooks") && r.Method == http.MethodPost:
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["url"] != hookURL || body["token"] != "gl-secret" {
+				http.Error(w, "bad hook", http.StatusBadRequest)
+				return
+			}
+			*created++
+			w.WriteHeader(http.StatusCreated)
+		case path == "/api/v4/groups/group/projects":
+			_, _ = w.Write([]byte(`[{"path_with_namespace":"group/idle"},{"path_with_namespace":"group/app"}]`))
+		case path == "/api/v4/projects/group%2Fidle/merge_requests":
+			_, _ = w.Write([]byte(`[]`))
+		case path == "/api/v4/projects/group%2Fapp/merge_requests":
+			_, _ = w.Write([]byte(`[{"iid":42}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	SetConfig(gitlabTestConfig{baseURL: srv.URL})
+	t.Cleanup(func() { SetConfig(testConfig{}) })
+
+	oldToken := gitlabWebhookToken
+	SetGitLabWebhookToken("gl-secret")
+	t.Cleanup(func() { SetGitLabWebhookToken(oldToken) })
+
+	oldRun := runSmokeTest
+	runSmokeTest = func(repo string, prNum int, providerType, token, engine string) {
+		*smokeTests = append(*smokeTests, repo)
+	}
+	t.Cleanup(func() { runSmokeTest = oldRun })
+
+	return created, smokeTests
+}
+
+func onboard(t *testing.T, body string) OnboardResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/admin/onboard", strings.NewReader(body))
+	req.Header.Set("X-Admin-Token", "admin-token")
+	req.Header.Set("PRIVATE-TOKEN", "admin-gl-token")
+	rr := httptest.NewRecorder()
+
+	HandleOnboard(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp OnboardResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return resp
+}
+
+func TestHandleOnboard_RequiresAdminToken(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/admin/onboard", strings.NewReader(`{"repo":"org/repo"}`))
+	req.Header.Set("X-Admin-Token", "wrong")
+	rr := httptest.NewRecorder()
+
+	HandleOnboard(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestHandleOnboard_RepoOrOrgRequired(t *testing.T) {
+	for _, body := range []string{`{}`, `{"repo":"org/repo","org":"org"}`} {
+		req := httptest.NewRequest(http.MethodPost, "/admin/onboard", strings.NewReader(body))
+		req.Header.Set("X-Admin-Token", "admin-token")
+		rr := httptest.NewRecorder()
+
+		HandleOnboard(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("body %s: expected 400, got %d", body, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "repo or org") {
+			t.Fatalf("body %s: expected repo/org error, got: %s", body, rr.Body.String())
+		}
+	}
+}
+
+func TestHandleOnboard_ProviderMismatch(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/admin/onboard", strings.NewReader(`{"repo":"group/project","provider":"gitlab"}`))
+	req.Header.Set("X-Admin-Token", "admin-token")
+	rr := httptest.NewRecorder()
+
+	HandleOnboard(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "does not match") {
+		t.Fatalf("expected provider mismatch error, got: %s", rr.Body.String())
+	}
+}
+
+func TestHandleOnboard_RequiresWebhookSecret(t *testing.T) {
+	oldSecret := webhookSecret
+	SetWebhookSecret("")
+	t.Cleanup(func() { SetWebhookSecret(oldSecret) })
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/onboard", strings.NewReader(`{"repo":"org/repo"}`))
+	req.Header.Set("X-Admin-Token", "admin-token")
+	req.Header.Set("X-Github-Token", "admin-gh-token")
+	rr := httptest.NewRecorder()
+
+	HandleOnboard(rr, req)
+
+	if rr.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412, got %d", rr.Code)
+	}
+}
+
+func TestHandleOnboard_CreatesWebhookAndStartsSmokeTest(t *testing.T) {
+	created, smokeTests := setupGitLabOnboard(t, false)
+
+	resp := onboard(t, `{"repo":"group/app"}`)
+
+	if !resp.WebhookCreated || *created != 1 {
+		t.Fatalf("expected webhook to be created once, created=%v count=%d", resp.WebhookCreated, *created)
+	}
+	if resp.SmokeTest != "started" || resp.SmokeTestRepo != "group/app" || resp.SmokeTestPR != 42 {
+		t.Fatalf("unexpected smoke test result: %+v", resp)
+	}
+	if len(*smokeTests) != 1 || (*smokeTests)[0] != "group/app" {
+		t.Fatalf("expected one smoke-test review on group/app, got %v", *smokeTests)
+	}
+	if _, ok := resp.ServiceConfig["review_mode"]; !ok {
+		t.Fatalf("expected service_config in response, got %+v", resp.ServiceConfig)
+	}
+}
+
+func TestHandleOnboard_ExistingWebhookOnLaterPage(t *testing.T) {
+	created, _ := setupGitLabOnboard(t, true)
+
+	resp := onboard(t, `{"repo":"group/app","skip_smoke_test":true}`)
+
+	if resp.WebhookCreated || *created != 0 {
+		t.Fatalf("expected existing webhook to be reused, created=%v count=%d", resp.WebhookCreated, *created)
+	}
+	if !strings.HasPrefix(resp.SmokeTest, "skipped") {
+		t.Fatalf("expected smoke test to be skipped, got %q", resp.SmokeTest)
+	}
+}
+
+func TestHandleOnboard_OrgSmokeTestsRecentRepoWithOpenPR(t *testing.T) {
+	created, smokeTests := setupGitLabOnboard(t, false)
+
+	resp := onboard(t, `{"org":"group"}`)
+
+	if resp.Scope != "org" || *created != 1 {
+		t.Fatalf("expected group webhook to be created, scope=%s count=%d", resp.Scope, *created)
+	}
+	if resp.SmokeTest != "started" || resp.SmokeTestRepo != "group/app" || resp.SmokeTestPR != 42 {
+		t.Fatalf("expected smoke test on group/app!42, got %+v", resp)
+	}
+	if len(*smokeTests) != 1 {
+		t.Fatalf("expected exactly one smoke-test review, got %v", *smokeTests)
+	}
+}
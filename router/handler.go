@@ -2,6 +2,7 @@ package router
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -56,10 +57,18 @@ type Config interface {
 	GetRepoCloneShallowClone() bool
 	GetRepoCloneShallowDepth() int
 	GetRepoCloneCleanupAfterReview() bool
+	GetRepoCloneDiffSource() string
 	// CodeGraph 集成配置
 	GetCodeGraphEnabled() bool
 	GetCodeGraphBinaryPath() string
 	GetCodeGraphIndexTimeout() int
+	// 超大 PR 摘要审查配置
+	GetLargePRMaxFiles() int
+	GetLargePRMaxChangedLines() int
+	GetLargePRMaxDiffBytes() int
+	// 管理接口配置
+	GetAdminToken() string
+	GetPublicURL() string
 }
 
 var appConfig Config
@@ -69,6 +78,14 @@ func SetConfig(cfg Config) {
 	appConfig = cfg
 }
 
+// modelWarmer 自托管模型预热器，未启用时为 nil
+var modelWarmer *lib.ModelWarmer
+
+// SetModelWarmer 设置模型预热器
+func SetModelWarmer(w *lib.ModelWarmer) {
+	modelWarmer = w
+}
+
 // HandleReview 处理 PR 审查请求
 func HandleReview(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -192,18 +209,19 @@ func ProcessReview(repo string, prNum int, providerType string, token string, re
 	}
 	var reviewContent string
 	var diffText string
+	var reviewedSHA string // diff 对应的 commit，为空时通过 API 获取
 	var err error
 
 	if reviewMode == "claude_cli" {
 		// Claude CLI 模式
-		reviewContent, diffText, err = processWithClaudeCLI(vcsClient, repo, prNum, token, providerType)
-		if err != nil {
+		reviewContent, diffText, reviewedSHA, err = processWithClaudeCLI(vcsClient, repo, prNum, token, providerType)
+		if err != nil && !isSummaryOnly(err) {
 			log.Printf("❌ [%s#%d] Claude CLI mode failed: %v", repo, prNum, err)
 			log.Printf("⚠️ [%s#%d] Attempting fallback to API mode...", repo, prNum)
 
 			// 降级到 API 模式
-			reviewContent, diffText, err = processWithAPI(vcsClient, repo, prNum)
-			if err != nil {
+			reviewContent, diffText, reviewedSHA, err = processWithAPI(vcsClient, repo, prNum)
+			if err != nil && !isSummaryOnly(err) {
 				log.Printf("❌ [%s#%d] API fallback also failed: %v", repo, prNum, err)
 				log.Printf("💥 [%s#%d] Review completely failed - both Claude CLI and API modes unsuccessful", repo, prNum)
 				return
@@ -211,14 +229,14 @@ func ProcessReview(repo string, prNum int, providerType string, token string, re
 		}
 	} else if reviewMode == "codex" {
 		// Codex CLI 模式
-		reviewContent, diffText, err = processWithCodexCLI(vcsClient, repo, prNum, token, providerType)
-		if err != nil {
+		reviewContent, diffText, reviewedSHA, err = processWithCodexCLI(vcsClient, repo, prNum, token, providerType)
+		if err != nil && !isSummaryOnly(err) {
 			log.Printf("❌ [%s#%d] Codex mode failed: %v", repo, prNum, err)
 			log.Printf("⚠️ [%s#%d] Attempting fallback to API mode...", repo, prNum)
 
 			// 降级到 API 模式
-			reviewContent, diffText, err = processWithAPI(vcsClient, repo, prNum)
-			if err != nil {
+			reviewContent, diffText, reviewedSHA, err = processWithAPI(vcsClient, repo, prNum)
+			if err != nil && !isSummaryOnly(err) {
 				log.Printf("❌ [%s#%d] API fallback also failed: %v", repo, prNum, err)
 				log.Printf("💥 [%s#%d] Review completely failed - both Codex and API modes unsuccessful", repo, prNum)
 				return
@@ -227,8 +245,8 @@ func ProcessReview(repo string, prNum int, providerType string, token string, re
 	} else {
 		// API 模式
 		log.Printf("🔧 [%s#%d] Using API mode (diff-based review)", repo, prNum)
-		reviewContent, diffText, err = processWithAPI(vcsClient, repo, prNum)
-		if err != nil {
+		reviewContent, diffText, reviewedSHA, err = processWithAPI(vcsClient, repo, prNum)
+		if err != nil && !isSummaryOnly(err) {
 			log.Printf("❌ [%s#%d] API review failed: %v", repo, prNum, err)
 			return
 		}
@@ -242,13 +260,32 @@ func ProcessReview(repo string, prNum int, providerType string, token string, re
 	// 若旧评论还在，本轮相同位置的问题会被误判为重复而静默跳过，导致问题丢失。
 	deleteOldBotComments(vcsClient, repo, prNum)
 
-	comment := fmt.Sprintf("🤖 **AI Code Review**\n\n%s", reviewContent)
-	if inlineMode {
-		headSHA, err := vcsClient.GetHeadSHA(repo, prNum)
-		if err != nil {
+	// 超大 PR：只发布摘要评论，不解析/发布行内评论
+	var summaryOnly *summaryOnlyError
+	if errors.As(err, &summaryOnly) {
+		comment := fmt.Sprintf("🤖 **AI Code Review**\n\n%s", summaryOnly.Content)
+		if err := vcsClient.PostComment(repo, prNum, comment); err != nil {
 			log.Printf("❌ [%s#%d] %v", repo, prNum, err)
 			return
 		}
+		log.Printf("✅ [%s#%d] Summary-only review posted (diff exceeds review limits)", repo, prNum)
+		return
+	}
+
+	comment := fmt.Sprintf("🤖 **AI Code Review**\n\n%s", reviewContent)
+	if inlineMode {
+		// 优先使用 diff 对应的 commit，保证行内评论的 position 与审查的代码一致
+		headSHA := reviewedSHA
+		if headSHA == "" {
+			if reviewMode == "claude_cli" || reviewMode == "codex" {
+				log.Printf("⚠️ [%s#%d] Reviewed commit unknown (API diff), inline comments anchored to current PR head", repo, prNum)
+			}
+			headSHA, err = vcsClient.GetHeadSHA(repo, prNum)
+			if err != nil {
+				log.Printf("❌ [%s#%d] %v", repo, prNum, err)
+				return
+			}
+		}
 
 		diffPositionMap := buildDiffPositionMap(diffText)
 		issues := parseIssuesFromReview(reviewContent)
@@ -1125,7 +1162,8 @@ func isDuplicateComment(existingComments []lib.Comment, filePath string, line in
 }
 
 // processWithAPI 使用 API 模式处理审查
-func processWithAPI(vcsClient lib.VCSProvider, repo string, prNum int) (reviewContent string, diffText string, err error) {
+// API 模式无法确定 diff 对应的 commit，headSHA 始终为空
+func processWithAPI(vcsClient lib.VCSProvider, repo string, prNum int) (reviewContent string, diffText string, headSHA string, err error) {
 	// 1. 获取 PR 详细信息
 	prInfo, err := vcsClient.GetPRInfo(repo, prNum)
 	if err != nil {
@@ -1139,7 +1177,12 @@ func processWithAPI(vcsClient lib.VCSProvider, repo string, prNum int) (reviewCo
 	diffText, err = vcsClient.GetDiff(repo, prNum)
 	if err != nil {
 		log.Printf("❌ [%s#%d] Failed to get diff: %v", repo, prNum, err)
-		return "", "", fmt.Errorf("failed to get diff: %w", err)
+		return "", "", "", fmt.Errorf("failed to get diff: %w", err)
+	}
+
+	// 超出审查上限时跳过模型审查，改为摘要评论
+	if limitErr := checkDiffLimits(diffText, prInfo, repo, prNum); limitErr != nil {
+		return "", diffText, "", limitErr
 	}
 
 	// 3. 增强 diff（添加 PR 上下文信息）
@@ -1157,21 +1200,25 @@ func processWithAPI(vcsClient lib.VCSProvider, repo string, prNum int) (reviewCo
 	enhancedDiff := enhancer.EnhanceDiff(diffText)
 
 	// 4. 调用 AI 审查（使用增强后的 diff）
+	// 模型长时间空闲时先预热，避免正式请求承担冷启动延迟
+	modelWarmer.WarmUpIfIdle()
+
 	log.Printf("🤖 [%s#%d] Starting AI review...", repo, prNum)
 	apiURL, apiKey, model, systemPrompt, userTemplate := appConfig.GetAIConfig()
 	aiClient := lib.NewAIClient(apiURL, apiKey, model, systemPrompt, userTemplate)
 	reviewContent, err = aiClient.ReviewCode(enhancedDiff)
 	if err != nil {
 		log.Printf("❌ [%s#%d] AI API call failed: %v", repo, prNum, err)
-		return "", "", fmt.Errorf("AI review failed: %w", err)
+		return "", "", "", fmt.Errorf("AI review failed: %w", err)
 	}
+	modelWarmer.MarkActive()
 
 	log.Printf("✅ [%s#%d] AI review completed", repo, prNum)
-	return reviewContent, diffText, nil
+	return reviewContent, diffText, "", nil
 }
 
 // processWithClaudeCLI 使用 Claude CLI 模式处理审查
-func processWithClaudeCLI(vcsClient lib.VCSProvider, repo string, prNum int, token, providerType string) (reviewContent string, diffText string, err error) {
+func processWithClaudeCLI(vcsClient lib.VCSProvider, repo string, prNum int, token, providerType string) (reviewContent string, diffText string, headSHA string, err error) {
 	// 获取 PR 详细信息
 	prInfo, err := vcsClient.GetPRInfo(repo, prNum)
 	if err != nil {
@@ -1185,21 +1232,21 @@ func processWithClaudeCLI(vcsClient lib.VCSProvider, repo string, prNum int, tok
 	branchInfo, err := vcsClient.GetBranchInfo(repo, prNum)
 	if err != nil {
 		log.Printf("❌ [%s#%d] Failed to get branch info: %v", repo, prNum, err)
-		return "", "", fmt.Errorf("failed to get branch info: %w", err)
+		return "", "", "", fmt.Errorf("failed to get branch info: %w", err)
 	}
 
 	// 获取克隆 URL
 	cloneURL, err := vcsClient.GetCloneURL(repo)
 	if err != nil {
 		log.Printf("❌ [%s#%d] Failed to get clone URL: %v", repo, prNum, err)
-		return "", "", fmt.Errorf("failed to get clone URL: %w", err)
+		return "", "", "", fmt.Errorf("failed to get clone URL: %w", err)
 	}
 
 	// 构建带认证的克隆 URL
 	authenticatedURL, err := lib.BuildCloneURL(cloneURL, token, providerType)
 	if err != nil {
 		log.Printf("❌ [%s#%d] Failed to build clone URL: %v", repo, prNum, err)
-		return "", "", fmt.Errorf("failed to build clone URL: %w", err)
+		return "", "", "", fmt.Errorf("failed to build clone URL: %w", err)
 	}
 
 	// 克隆仓库
@@ -1213,7 +1260,7 @@ func processWithClaudeCLI(vcsClient lib.VCSProvider, repo string, prNum int, tok
 	workDir, err := repoManager.CloneAndCheckout(authenticatedURL, *branchInfo)
 	if err != nil {
 		log.Printf("❌ [%s#%d] Clone failed: %v", repo, prNum, err)
-		return "", "", fmt.Errorf("failed to clone repository: %w", err)
+		return "", "", "", fmt.Errorf("failed to clone repository: %w", err)
 	}
 
 	// 清理工作目录（defer）
@@ -1225,17 +1272,15 @@ func processWithClaudeCLI(vcsClient lib.VCSProvider, repo string, prNum int, tok
 		}()
 	}
 
-	// 从本地仓库获取完整 diff（源分支 vs 目标分支自分叉点起的全部变更，不受 API 限制）
-	log.Printf("🔍 [%s#%d] Getting full diff from local repository...", repo, prNum)
-	diffText, err = repoManager.GetDiffFromLocalRepo(workDir, branchInfo.SourceBranch, branchInfo.TargetBranch)
+	// 按配置的来源获取 diff（默认在本地仓库计算，与检出的代码保持一致）
+	diffText, headSHA, err = loadDiff(vcsClient, repoManager, workDir, *branchInfo, repo, prNum)
 	if err != nil {
-		log.Printf("⚠️ [%s#%d] Failed to get local diff: %v, falling back to API", repo, prNum, err)
-		// 降级到 API 方式
-		diffText, err = vcsClient.GetDiff(repo, prNum)
-		if err != nil {
-			log.Printf("❌ [%s#%d] Failed to get diff from API: %v", repo, prNum, err)
-			return "", "", fmt.Errorf("failed to get diff: %w", err)
-		}
+		return "", "", "", err
+	}
+
+	// 超出审查上限时跳过模型审查，改为摘要评论
+	if limitErr := checkDiffLimits(diffText, prInfo, repo, prNum); limitErr != nil {
+		return "", diffText, headSHA, limitErr
 	}
 
 	// 构建上下文增强和引导信息
@@ -1306,19 +1351,19 @@ func processWithClaudeCLI(vcsClient lib.VCSProvider, repo string, prNum int, tok
 	result, err := cliClient.ReviewCodeInRepo(workDir, fullContext, "", cgMCPConfig, cgAllowedTools)
 	if err != nil {
 		log.Printf("❌ [%s#%d] Claude review failed: %v", repo, prNum, err)
-		return "", "", fmt.Errorf("Claude CLI review failed: %w", err)
+		return "", "", "", fmt.Errorf("Claude CLI review failed: %w", err)
 	}
 
 	if !result.Success {
 		log.Printf("❌ [%s#%d] Claude review unsuccessful: %v", repo, prNum, result.Error)
-		return "", "", fmt.Errorf("Claude CLI review unsuccessful: %v", result.Error)
+		return "", "", "", fmt.Errorf("Claude CLI review unsuccessful: %v", result.Error)
 	}
 
-	return result.Content, diffText, nil
+	return result.Content, diffText, headSHA, nil
 }
 
 // processWithCodexCLI 使用 Codex CLI 模式处理审查
-func processWithCodexCLI(vcsClient lib.VCSProvider, repo string, prNum int, token, providerType string) (reviewContent string, diffText string, err error) {
+func processWithCodexCLI(vcsClient lib.VCSProvider, repo string, prNum int, token, providerType string) (reviewContent string, diffText string, headSHA string, err error) {
 	// 获取 PR 详细信息
 	prInfo, err := vcsClient.GetPRInfo(repo, prNum)
 	if err != nil {
@@ -1332,21 +1377,21 @@ func processWithCodexCLI(vcsClient lib.VCSProvider, repo string, prNum int, toke
 	branchInfo, err := vcsClient.GetBranchInfo(repo, prNum)
 	if err != nil {
 		log.Printf("❌ [%s#%d] Failed to get branch info: %v", repo, prNum, err)
-		return "", "", fmt.Errorf("failed to get branch info: %w", err)
+		return "", "", "", fmt.Errorf("failed to get branch info: %w", err)
 	}
 
 	// 获取克隆 URL
 	cloneURL, err := vcsClient.GetCloneURL(repo)
 	if err != nil {
 		log.Printf("❌ [%s#%d] Failed to get clone URL: %v", repo, prNum, err)
-		return "", "", fmt.Errorf("failed to get clone URL: %w", err)
+		return "", "", "", fmt.Errorf("failed to get clone URL: %w", err)
 	}
 
 	// 构建带认证的克隆 URL
 	authenticatedURL, err := lib.BuildCloneURL(cloneURL, token, providerType)
 	if err != nil {
 		log.Printf("❌ [%s#%d] Failed to build clone URL: %v", repo, prNum, err)
-		return "", "", fmt.Errorf("failed to build clone URL: %w", err)
+		return "", "", "", fmt.Errorf("failed to build clone URL: %w", err)
 	}
 
 	// 克隆仓库
@@ -1360,7 +1405,7 @@ func processWithCodexCLI(vcsClient lib.VCSProvider, repo string, prNum int, toke
 	workDir, err := repoManager.CloneAndCheckout(authenticatedURL, *branchInfo)
 	if err != nil {
 		log.Printf("❌ [%s#%d] Clone failed: %v", repo, prNum, err)
-		return "", "", fmt.Errorf("failed to clone repository: %w", err)
+		return "", "", "", fmt.Errorf("failed to clone repository: %w", err)
 	}
 
 	// 清理工作目录（defer）
@@ -1372,17 +1417,15 @@ func processWithCodexCLI(vcsClient lib.VCSProvider, repo string, prNum int, toke
 		}()
 	}
 
-	// 从本地仓库获取完整 diff（源分支 vs 目标分支自分叉点起的全部变更，不受 API 限制）
-	log.Printf("🔍 [%s#%d] Getting full diff from local repository...", repo, prNum)
-	diffText, err = repoManager.GetDiffFromLocalRepo(workDir, branchInfo.SourceBranch, branchInfo.TargetBranch)
+	// 按配置的来源获取 diff（默认在本地仓库计算，与检出的代码保持一致）
+	diffText, headSHA, err = loadDiff(vcsClient, repoManager, workDir, *branchInfo, repo, prNum)
 	if err != nil {
-		log.Printf("⚠️ [%s#%d] Failed to get local diff: %v, falling back to API", repo, prNum, err)
-		// 降级到 API 方式
-		diffText, err = vcsClient.GetDiff(repo, prNum)
-		if err != nil {
-			log.Printf("❌ [%s#%d] Failed to get diff from API: %v", repo, prNum, err)
-			return "", "", fmt.Errorf("failed to get diff: %w", err)
-		}
+		return "", "", "", err
+	}
+
+	// 超出审查上限时跳过模型审查，改为摘要评论
+	if limitErr := checkDiffLimits(diffText, prInfo, repo, prNum); limitErr != nil {
+		return "", diffText, headSHA, limitErr
 	}
 
 	// 构建上下文增强和引导信息
@@ -1451,15 +1494,15 @@ func processWithCodexCLI(vcsClient lib.VCSProvider, repo string, prNum int, toke
 	result, err := cliClient.ReviewCodeInRepo(workDir, branchInfo.TargetBranch, fullContext, cgConfigArgs)
 	if err != nil {
 		log.Printf("❌ [%s#%d] Codex review failed: %v", repo, prNum, err)
-		return "", "", fmt.Errorf("Codex CLI review failed: %w", err)
+		return "", "", "", fmt.Errorf("Codex CLI review failed: %w", err)
 	}
 
 	if !result.Success {
 		log.Printf("❌ [%s#%d] Codex review unsuccessful: %v", repo, prNum, result.Error)
-		return "", "", fmt.Errorf("Codex CLI review unsuccessful: %v", result.Error)
+		return "", "", "", fmt.Errorf("Codex CLI review unsuccessful: %v", result.Error)
 	}
 
-	return result.Content, diffText, nil
+	return result.Content, diffText, headSHA, nil
 }
 
 // fetchOthersComments 获取其他人（非当前认证用户）的评论
@@ -1522,6 +1565,65 @@ func chooseEngineLabel(engine string) string {
 	return engine
 }
 
+// loadDiff 按配置的 diff 来源获取 diff 及其对应的 commit SHA。
+// local 来源失败时直接返回错误，不在这里静默改用 API diff：
+// 由 ProcessReview 显式降级到 API 模式，避免 CLI 审查的代码与 diff、行内评论位置不一致。
+func loadDiff(vcsClient lib.VCSProvider, repoManager *lib.RepoManager, workDir string, branchInfo lib.BranchInfo, repo string, prNum int) (diffText string, headSHA string, err error) {
+	var source lib.DiffSource
+	if appConfig.GetRepoCloneDiffSource() == lib.DiffSourceAPI {
+		source = lib.NewAPIDiffSource(vcsClient)
+	} else {
+		source = lib.NewLocalGitDiffSource(repoManager, workDir, branchInfo)
+	}
+
+	log.Printf("🔍 [%s#%d] Getting diff from %s source...", repo, prNum, source.Name())
+	diffText, headSHA, err = source.GetDiff(repo, prNum)
+	if err == nil {
+		return diffText, headSHA, nil
+	}
+	if source.Name() == lib.DiffSourceAPI {
+		log.Printf("❌ [%s#%d] Failed to get diff from API: %v", repo, prNum, err)
+		return "", "", fmt.Errorf("failed to get diff: %w", err)
+	}
+
+	log.Printf("❌ [%s#%d] Failed to get local diff: %v", repo, prNum, err)
+	return "", "", fmt.Errorf("failed to get local diff: %w", err)
+}
+
+// summaryOnlyError 表示 diff 超出审查上限，应跳过模型审查，直接发布摘要评论
+type summaryOnlyError struct {
+	Content string
+}
+
+func (e *summaryOnlyError) Error() string {
+	return "diff exceeds review limits, switching to summary-only review"
+}
+
+func isSummaryOnly(err error) bool {
+	var summaryOnly *summaryOnlyError
+	return errors.As(err, &summaryOnly)
+}
+
+// checkDiffLimits 检查 diff 规模，超出上限时返回携带摘要评论的 summaryOnlyError
+// prInfo 中的平台统计用于弥补被截断的 API diff，可为 nil
+func checkDiffLimits(diffText string, prInfo *lib.PRInfo, repo string, prNum int) error {
+	limits := lib.DiffLimits{
+		MaxFiles:        appConfig.GetLargePRMaxFiles(),
+		MaxChangedLines: appConfig.GetLargePRMaxChangedLines(),
+		MaxDiffBytes:    appConfig.GetLargePRMaxDiffBytes(),
+	}
+	summaries := lib.ParseFileSummaries(diffText)
+	scale := lib.MeasureDiff(diffText, summaries, prInfo)
+	reasons := limits.Check(diffText, scale)
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	log.Printf("⚠️ [%s#%d] Diff exceeds review limits (%s), switching to summary-only review",
+		repo, prNum, strings.Join(reasons, "; "))
+	return &summaryOnlyError{Content: lib.BuildSummaryOnlyReview(summaries, reasons, limits, scale)}
+}
+
 // buildCodeGraphManager 根据配置创建 codegraph 管理器（未启用时仍返回非 nil 句柄）
 func buildCodeGraphManager() *lib.CodeGraphManager {
 	return lib.NewCodeGraphManager(lib.CodeGraphConfig{
@@ -3,6 +3,7 @@ package router
 import (
 	"net/http"
 	"net/http/httptest"
+	"pr-review/lib"
 	"strings"
 	"testing"
 )
@@ -43,9 +44,15 @@ func (testConfig) GetRepoCloneTimeout() int                { return 60 }
 func (testConfig) GetRepoCloneShallowClone() bool          { return true }
 func (testConfig) GetRepoCloneShallowDepth() int           { return 1 }
 func (testConfig) GetRepoCloneCleanupAfterReview() bool    { return true }
+func (testConfig) GetRepoCloneDiffSource() string          { return "local" }
 func (testConfig) GetCodeGraphEnabled() bool               { return false }
 func (testConfig) GetCodeGraphBinaryPath() string          { return "codegraph" }
 func (testConfig) GetCodeGraphIndexTimeout() int           { return 600 }
+func (testConfig) GetLargePRMaxFiles() int                 { return 2 }
+func (testConfig) GetLargePRMaxChangedLines() int          { return 100 }
+func (testConfig) GetLargePRMaxDiffBytes() int             { return -1 }
+func (testConfig) GetAdminToken() string                   { return "admin-token" }
+func (testConfig) GetPublicURL() string                    { return "https://review.example.com" }
 
 func init() {
 	SetConfig(testConfig{})
@@ -179,3 +186,63 @@ func TestParseIssuesFromReview_RangeLineNumberNotDropped(t *testing.T) {
 		t.Errorf("range 9-31 newLine = %d, want 9", issues[2].NewLine)
 	}
 }
+
+func TestCheckDiffLimits_SummaryOnlyWhenTooManyFiles(t *testing.T) {
+	var diff strings.Builder
+	for _, name := range []string{"a.go", "b.go", "gen/c.pb.go"} {
+		diff.WriteString("diff --git a/" + name + " b/" + name + "\n")
+		diff.WriteString("--- a/" + name + "\n")
+		diff.WriteString("+++ b/" + name + "\n")
+		diff.WriteString("@@ -1,1 +1,2 @@\n line\n+added\n")
+	}
+
+	err := checkDiffLimits(diff.String(), nil, "org/repo", 1)
+	if !isSummaryOnly(err) {
+		t.Fatalf("expected summary-only error, got %v", err)
+	}
+	content := err.(*summaryOnlyError).Content
+	for _, want := range []string{"超大 PR", "变更文件数 3，超过上限 2", "`gen/c.pb.go`", "生成文件", "拆分"} {
+		if !strings.Contains(content, want) {
+			t.Fatalf("expected summary to contain %q, got:\n%s", want, content)
+		}
+	}
+}
+
+func TestCheckDiffLimits_WithinLimits(t *testing.T) {
+	diff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1,1 +1,2 @@\n line\n+added\n"
+	if err := checkDiffLimits(diff, nil, "org/repo", 1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestCheckDiffLimits_TruncatedAPIDiff(t *testing.T) {
+	// API 返回的 diff 已被截断：解析出的规模远小于上限，但仍不能做逐行审查
+	diff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1,1 +1,2 @@\n line\n+added\n\n\n" + lib.DiffTruncatedNotice
+
+	err := checkDiffLimits(diff, nil, "org/repo", 1)
+	if !isSummaryOnly(err) {
+		t.Fatalf("expected summary-only error for truncated diff, got %v", err)
+	}
+	content := err.(*summaryOnlyError).Content
+	for _, want := range []string{"已被截断", "diff 已截断，以下仅基于获取到的 1 个文件", "仅覆盖获取到的部分文件"} {
+		if !strings.Contains(content, want) {
+			t.Fatalf("expected summary to contain %q, got:\n%s", want, content)
+		}
+	}
+}
+
+func TestCheckDiffLimits_UsesPRMetadata(t *testing.T) {
+	diff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1,1 +1,2 @@\n line\n+added\n"
+	prInfo := &lib.PRInfo{ChangedFiles: 500, Additions: 30000, Deletions: 100}
+
+	err := checkDiffLimits(diff, prInfo, "org/repo", 1)
+	if !isSummaryOnly(err) {
+		t.Fatalf("expected summary-only error from PR metadata, got %v", err)
+	}
+	content := err.(*summaryOnlyError).Content
+	for _, want := range []string{"变更文件数 500，超过上限 2", "变更行数 30100，超过上限 100", "共 500 个"} {
+		if !strings.Contains(content, want) {
+			t.Fatalf("expected summary to contain %q, got:\n%s", want, content)
+		}
+	}
+}